@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blent/beagle/server/storage"
+	"github.com/blent/beagle/server/storage/migrate"
+	"github.com/blent/beagle/server/storage/providers/mysql"
+	"github.com/blent/beagle/server/storage/providers/postgres"
+	"github.com/blent/beagle/server/storage/providers/sqlite"
+	"github.com/pkg/errors"
+)
+
+// RunMigrateCommand implements the `migrate up|down|status` subcommand. It
+// opens the configured storage provider directly (bypassing the running
+// server) so schema changes can be rolled out ahead of a deploy.
+func RunMigrateCommand(ctx context.Context, settings *storage.Settings, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate <up|down|status> [version]")
+	}
+
+	runner, db, err := newMigrationRunner(settings)
+
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	switch args[0] {
+	case "status":
+		version, err := runner.Status(ctx)
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("schema version: %d\n", version)
+
+		return nil
+	case "up":
+		return runner.Migrate(ctx, -1)
+	case "down":
+		if len(args) < 2 {
+			return errors.New("usage: migrate down <version>")
+		}
+
+		var target int
+
+		if _, err := fmt.Sscanf(args[1], "%d", &target); err != nil {
+			return errors.Wrap(err, "invalid target version")
+		}
+
+		return runner.Migrate(ctx, target)
+	default:
+		return errors.Errorf("unknown migrate subcommand %q", args[0])
+	}
+}
+
+func newMigrationRunner(settings *storage.Settings) (*migrate.Runner, interface{ Close() error }, error) {
+	switch settings.Provider {
+	case "sqlite3":
+		db, err := sqlite.OpenDB(settings.ConnectionString)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return migrate.NewRunner(db, "sqlite3", migrate.SQLiteMigrations), db, nil
+	case "postgres":
+		db, err := postgres.OpenDB(settings.ConnectionString)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return migrate.NewRunner(db, "postgres", postgres.PostgresMigrations), db, nil
+	case "mysql":
+		db, err := mysql.OpenDB(settings.ConnectionString)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return migrate.NewRunner(db, "mysql", mysql.MySQLMigrations), db, nil
+	default:
+		return nil, nil, errors.Errorf("not supported storage provider %q", settings.Provider)
+	}
+}
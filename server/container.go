@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"github.com/blent/beagle/pkg/delivery"
 	"github.com/blent/beagle/pkg/discovery/devices"
 	"github.com/blent/beagle/pkg/history/activity"
@@ -13,7 +14,10 @@ import (
 	"github.com/blent/beagle/server/initialization"
 	"github.com/blent/beagle/server/initialization/initializers"
 	"github.com/blent/beagle/server/storage"
+	"github.com/blent/beagle/server/storage/providers/mysql"
+	"github.com/blent/beagle/server/storage/providers/postgres"
 	"github.com/blent/beagle/server/storage/providers/sqlite"
+	storagewatch "github.com/blent/beagle/src/server/storage"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"path"
@@ -27,6 +31,7 @@ type Container struct {
 	tracker         *tracking.Tracker
 	eventBroker     *notification.Broker
 	storageProvider storage.Provider
+	storageWatcher  storagewatch.Watcher
 	activityService *activityMonitor.Monitoring
 	activityWriter  *activity.Writer
 	server          *http.Server
@@ -54,7 +59,7 @@ func NewContainer(settings *Settings) (*Container, error) {
 	tracker := tracking.NewTracker(logger.Named("tracker"), device, settings.Tracking)
 
 	// Storage
-	storageProvider, err := createStorageProvider(settings.Storage)
+	storageProvider, storageWatcher, err := createStorageProvider(settings.Storage)
 
 	if err != nil {
 		return nil, err
@@ -97,6 +102,9 @@ func NewContainer(settings *Settings) (*Container, error) {
 		return nil, err
 	}
 
+	// React to endpoint/peripheral changes without polling.
+	go watchStorageChanges(logger.Named("storage:watcher"), storageWatcher, registry)
+
 	// Http
 	var webServer *http.Server
 
@@ -141,18 +149,71 @@ func NewContainer(settings *Settings) (*Container, error) {
 		tracker,
 		eventBroker,
 		storageProvider,
+		storageWatcher,
 		activityService,
 		activityWriter,
 		webServer,
 	}, nil
 }
 
-func createStorageProvider(settings *storage.Settings) (storage.Provider, error) {
+// watchStorageChanges subscribes to endpoint/peripheral mutations for the
+// lifetime of the process, invalidating registry's cached endpoint lookups
+// as they come in so the broker never acts on stale data, without having
+// to poll storage.
+func watchStorageChanges(logger *zap.Logger, watcher storagewatch.Watcher, registry *Registry) {
+	consumer, err := watcher.Subscribe(context.Background(), storagewatch.Filter{
+		Kinds: []storagewatch.EntityKind{storagewatch.EntityKindEndpoint, storagewatch.EntityKindPeripheral},
+	})
+
+	if err != nil {
+		logger.Error("failed to subscribe to storage changes", zap.Error(err))
+		return
+	}
+
+	for event := range consumer {
+		logger.Debug(
+			"storage change received",
+			zap.String("kind", string(event.EntityKind)),
+			zap.String("op", string(event.Op)),
+			zap.Uint64("id", event.ID),
+		)
+
+		registry.Invalidate(event.EntityKind, event.ID)
+	}
+}
+
+// createStorageProvider constructs the configured storage.Provider along
+// with its change watcher. The watcher is read off the concrete provider
+// before it's narrowed to the storage.Provider interface, since the
+// interface itself only needs to cover what storage.Manager uses.
+func createStorageProvider(settings *storage.Settings) (storage.Provider, storagewatch.Watcher, error) {
 	switch settings.Provider {
 	case "sqlite3":
-		return sqlite.NewSQLiteProvider(settings.ConnectionString)
+		provider, err := sqlite.NewSQLiteProvider(settings.ConnectionString)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return provider, provider.GetWatcher(), nil
+	case "postgres":
+		provider, err := postgres.NewPostgresProvider(settings.ConnectionString)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return provider, provider.GetWatcher(), nil
+	case "mysql":
+		provider, err := mysql.NewMySQLProvider(settings.ConnectionString)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return provider, provider.GetWatcher(), nil
 	default:
-		return nil, errors.New("Not supported storage provider")
+		return nil, nil, errors.New("Not supported storage provider")
 	}
 }
 
@@ -176,6 +237,10 @@ func (c *Container) GetStorageProvider() storage.Provider {
 	return c.storageProvider
 }
 
+func (c *Container) GetStorageWatcher() storagewatch.Watcher {
+	return c.storageWatcher
+}
+
 func (c *Container) GetActivityService() *activityMonitor.Monitoring {
 	return c.activityService
 }
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"sync"
+
+	outerstorage "github.com/blent/beagle/server/storage"
+	"github.com/blent/beagle/src/core/notification"
+	storagewatch "github.com/blent/beagle/src/server/storage"
+)
+
+// Registry caches endpoint lookups in front of storage.Manager so the
+// notification broker isn't round-tripping to the database on every
+// delivery, and drops stale entries when storage reports a change.
+type Registry struct {
+	manager *outerstorage.Manager
+
+	mu        sync.RWMutex
+	endpoints map[uint64]*notification.Endpoint
+}
+
+func NewRegistry(manager *outerstorage.Manager) (*Registry, error) {
+	return &Registry{
+		manager:   manager,
+		endpoints: make(map[uint64]*notification.Endpoint),
+	}, nil
+}
+
+// GetEndpoint returns the endpoint for id, serving from cache when
+// possible and falling back to storage on a miss.
+func (r *Registry) GetEndpoint(id uint64) (*notification.Endpoint, error) {
+	r.mu.RLock()
+	endpoint, ok := r.endpoints[id]
+	r.mu.RUnlock()
+
+	if ok {
+		return endpoint, nil
+	}
+
+	endpoint, err := r.manager.GetEndpoint(context.Background(), id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.endpoints[id] = endpoint
+	r.mu.Unlock()
+
+	return endpoint, nil
+}
+
+// Invalidate drops id from the endpoint cache, so the next GetEndpoint
+// call re-reads storage instead of serving a stale entry. It's a no-op for
+// entity kinds the registry doesn't cache.
+func (r *Registry) Invalidate(kind storagewatch.EntityKind, id uint64) {
+	if kind != storagewatch.EntityKindEndpoint {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.endpoints, id)
+}
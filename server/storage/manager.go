@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/url"
+
+	"github.com/blent/beagle/src/core/notification"
+	innerstorage "github.com/blent/beagle/src/server/storage"
+	"github.com/blent/beagle/src/server/storage/providers/sqlite/repositories"
+	"go.uber.org/zap"
+)
+
+// Manager is the single entry point route handlers, the event broker and
+// anything else outside the storage package use to reach the endpoint
+// repository. Every method takes a ctx and forwards it unchanged, so a
+// cancelled HTTP request or a shutdown signal actually cancels the
+// in-flight query instead of only failing to observe its result.
+type Manager struct {
+	logger    *zap.Logger
+	endpoints *repositories.SQLiteEndpointRepository
+}
+
+// NewManager wraps the endpoint repository provider exposes behind the
+// ctx-aware API the rest of the application calls into.
+func NewManager(logger *zap.Logger, provider Provider) *Manager {
+	return &Manager{logger, provider.GetEndpointRepository()}
+}
+
+func (m *Manager) GetEndpoint(ctx context.Context, id uint64) (*notification.Endpoint, error) {
+	return m.endpoints.Get(ctx, id)
+}
+
+func (m *Manager) FindEndpoints(ctx context.Context, query *innerstorage.EndpointQuery) ([]*notification.Endpoint, string, error) {
+	return m.endpoints.Find(ctx, query)
+}
+
+// FindEndpointsByParams parses HTTP query string values into an
+// EndpointQuery and runs it, so a route handler can forward r.URL.Query()
+// straight through to expose the name/url_prefix/method/header/
+// created_from/created_to filters and cursor pagination on the
+// /registry/endpoints route.
+func (m *Manager) FindEndpointsByParams(ctx context.Context, values url.Values) ([]*notification.Endpoint, string, error) {
+	query, err := innerstorage.ParseEndpointQueryParams(values)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return m.FindEndpoints(ctx, query)
+}
+
+func (m *Manager) CountEndpoints(ctx context.Context) (uint64, error) {
+	return m.endpoints.Count(ctx)
+}
+
+func (m *Manager) CreateEndpoint(ctx context.Context, endpoint *notification.Endpoint, tx *sql.Tx) (uint64, error) {
+	return m.endpoints.Create(ctx, endpoint, tx)
+}
+
+func (m *Manager) CreateEndpoints(ctx context.Context, endpoints []*notification.Endpoint, tx *sql.Tx) ([]uint64, error) {
+	return m.endpoints.CreateMany(ctx, endpoints, tx)
+}
+
+func (m *Manager) UpsertEndpoints(ctx context.Context, endpoints []*notification.Endpoint, tx *sql.Tx) error {
+	return m.endpoints.UpsertMany(ctx, endpoints, tx)
+}
+
+func (m *Manager) UpdateEndpoint(ctx context.Context, endpoint *notification.Endpoint, tx *sql.Tx) error {
+	return m.endpoints.Update(ctx, endpoint, tx)
+}
+
+func (m *Manager) DeleteEndpoint(ctx context.Context, id uint64, tx *sql.Tx) error {
+	return m.endpoints.Delete(ctx, id, tx)
+}
+
+func (m *Manager) DeleteEndpoints(ctx context.Context, ids []uint64, tx *sql.Tx) error {
+	return m.endpoints.DeleteMany(ctx, ids, tx)
+}
+
+// ImportResult reports the outcome of a single endpoint within an
+// ImportEndpoints call, keyed by its position in the import payload.
+type ImportResult struct {
+	Index int
+	Id    uint64
+	Error string
+}
+
+// ImportEndpoints decodes a JSON import payload and upserts it, for the
+// bulk import route to return per-item results for. It tries the
+// single-transaction UpsertMany first since that's one round trip for the
+// common all-valid case; if that fails, it falls back to applying each
+// endpoint individually so one bad row doesn't sink the rest of the batch.
+func (m *Manager) ImportEndpoints(ctx context.Context, r io.Reader) ([]ImportResult, error) {
+	endpoints, err := innerstorage.DecodeEndpointImport(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ImportResult, len(endpoints))
+
+	if err := m.UpsertEndpoints(ctx, endpoints, nil); err == nil {
+		for i, endpoint := range endpoints {
+			results[i] = ImportResult{Index: i, Id: endpoint.Id}
+		}
+
+		return results, nil
+	}
+
+	for i, endpoint := range endpoints {
+		var id uint64
+		var err error
+
+		if endpoint.Id > 0 {
+			id, err = endpoint.Id, m.UpdateEndpoint(ctx, endpoint, nil)
+		} else {
+			id, err = m.CreateEndpoint(ctx, endpoint, nil)
+		}
+
+		if err != nil {
+			results[i] = ImportResult{Index: i, Error: err.Error()}
+			continue
+		}
+
+		results[i] = ImportResult{Index: i, Id: id}
+	}
+
+	return results, nil
+}
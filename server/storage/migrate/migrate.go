@@ -0,0 +1,189 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// schemaVersionTable tracks the highest migration version applied to the
+// database. It is created on first use of Migrate.
+const schemaVersionTable = "schema_version"
+
+// Migration is a single, ordered schema change. Version must be unique and
+// is applied in ascending order; Down reverses Up when migrating backwards.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// ErrUnknownVersion is returned when Migrate is asked to reach a target
+// version that doesn't exist in the supplied set of migrations.
+var ErrUnknownVersion = errors.New("unknown migration version")
+
+// Runner applies a set of Migrations to a database, tracking the applied
+// version in schemaVersionTable so restarts and redeploys are idempotent.
+type Runner struct {
+	db         *sql.DB
+	driver     string
+	migrations []Migration
+}
+
+// NewRunner creates a Runner for driver ("sqlite3", "postgres" or "mysql")
+// over the given migrations, sorted ascending by Version.
+func NewRunner(db *sql.DB, driver string, migrations []Migration) *Runner {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	return &Runner{db, driver, sorted}
+}
+
+// Status returns the currently applied schema version, or 0 if the schema
+// version table doesn't exist yet.
+func (r *Runner) Status(ctx context.Context) (int, error) {
+	if err := r.ensureVersionTable(ctx); err != nil {
+		return 0, err
+	}
+
+	var version int
+
+	row := r.db.QueryRowContext(ctx, "SELECT version FROM "+schemaVersionTable+" LIMIT 1")
+
+	if err := row.Scan(&version); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// Migrate brings the schema to targetVersion, running Up migrations when
+// moving forward and Down migrations when moving backward. targetVersion
+// of -1 means "the latest known version".
+func (r *Runner) Migrate(ctx context.Context, targetVersion int) error {
+	if targetVersion == -1 && len(r.migrations) > 0 {
+		targetVersion = r.migrations[len(r.migrations)-1].Version
+	}
+
+	if targetVersion != 0 && !r.hasVersion(targetVersion) {
+		return ErrUnknownVersion
+	}
+
+	current, err := r.Status(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	if current == targetVersion {
+		return nil
+	}
+
+	if current < targetVersion {
+		return r.upgrade(ctx, current, targetVersion)
+	}
+
+	return r.downgrade(ctx, current, targetVersion)
+}
+
+func (r *Runner) upgrade(ctx context.Context, from, to int) error {
+	for _, m := range r.migrations {
+		if m.Version <= from || m.Version > to {
+			continue
+		}
+
+		if err := r.apply(ctx, m.Up, m.Version); err != nil {
+			return errors.Wrapf(err, "applying migration %d (%s)", m.Version, m.Name)
+		}
+	}
+
+	return nil
+}
+
+func (r *Runner) downgrade(ctx context.Context, from, to int) error {
+	for i := len(r.migrations) - 1; i >= 0; i-- {
+		m := r.migrations[i]
+
+		if m.Version > from || m.Version <= to {
+			continue
+		}
+
+		// Record the version actually reached after reverting m, not the
+		// final target, so a crash partway through a multi-step downgrade
+		// leaves schema_version matching the real schema instead of
+		// falsely claiming the whole downgrade completed.
+		newVersion := to
+
+		if i > 0 && r.migrations[i-1].Version > to {
+			newVersion = r.migrations[i-1].Version
+		}
+
+		if err := r.apply(ctx, m.Down, newVersion); err != nil {
+			return errors.Wrapf(err, "reverting migration %d (%s)", m.Version, m.Name)
+		}
+	}
+
+	return nil
+}
+
+// hasVersion reports whether version is one of the runner's known
+// migration versions.
+func (r *Runner) hasVersion(version int) bool {
+	for _, m := range r.migrations {
+		if m.Version == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Runner) apply(ctx context.Context, statement string, newVersion int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, statement); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+schemaVersionTable); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO "+schemaVersionTable+" (version) VALUES ("+r.versionPlaceholder()+")", newVersion); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *Runner) versionPlaceholder() string {
+	if r.driver == "postgres" {
+		return "$1"
+	}
+
+	return "?"
+}
+
+func (r *Runner) ensureVersionTable(ctx context.Context) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		"CREATE TABLE IF NOT EXISTS "+schemaVersionTable+" (version INTEGER NOT NULL)",
+	)
+
+	return err
+}
@@ -0,0 +1,95 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func testMigrations() []Migration {
+	return []Migration{
+		{Version: 1, Name: "create_t", Up: "CREATE TABLE t (id INTEGER)", Down: "DROP TABLE t"},
+		{Version: 2, Name: "add_col", Up: "ALTER TABLE t ADD COLUMN name TEXT", Down: "ALTER TABLE t DROP COLUMN name"},
+		{Version: 3, Name: "add_col2", Up: "ALTER TABLE t ADD COLUMN url TEXT", Down: "ALTER TABLE t DROP COLUMN url"},
+	}
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestMigrateUpgradeRecordsLatestVersion(t *testing.T) {
+	db := openTestDB(t)
+	runner := NewRunner(db, "sqlite3", testMigrations())
+
+	if err := runner.Migrate(context.Background(), -1); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	version, err := runner.Status(context.Background())
+
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+
+	if version != 3 {
+		t.Fatalf("expected version 3, got %d", version)
+	}
+}
+
+func TestMigrateDowngradeRecordsTargetVersion(t *testing.T) {
+	db := openTestDB(t)
+	runner := NewRunner(db, "sqlite3", testMigrations())
+
+	if err := runner.Migrate(context.Background(), 3); err != nil {
+		t.Fatalf("upgrade failed: %v", err)
+	}
+
+	if err := runner.Migrate(context.Background(), 1); err != nil {
+		t.Fatalf("downgrade failed: %v", err)
+	}
+
+	version, err := runner.Status(context.Background())
+
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+
+	if version != 1 {
+		t.Fatalf("expected version 1 after downgrade, got %d", version)
+	}
+}
+
+func TestMigrateUnknownTargetVersion(t *testing.T) {
+	db := openTestDB(t)
+	runner := NewRunner(db, "sqlite3", testMigrations())
+
+	if err := runner.Migrate(context.Background(), 99); err != ErrUnknownVersion {
+		t.Fatalf("expected ErrUnknownVersion, got %v", err)
+	}
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	db := openTestDB(t)
+	runner := NewRunner(db, "sqlite3", testMigrations())
+
+	if err := runner.Migrate(context.Background(), -1); err != nil {
+		t.Fatalf("first Migrate failed: %v", err)
+	}
+
+	if err := runner.Migrate(context.Background(), -1); err != nil {
+		t.Fatalf("second Migrate failed: %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package migrate
+
+// SQLiteMigrations are the ordered schema migrations for the sqlite3
+// storage provider. New fields land here instead of being bolted onto the
+// original CREATE TABLE statements, so existing deployments upgrade in
+// place.
+var SQLiteMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_endpoints",
+		Up: `CREATE TABLE IF NOT EXISTS endpoints (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			url TEXT NOT NULL,
+			method TEXT NOT NULL,
+			headers TEXT
+		)`,
+		Down: `DROP TABLE IF EXISTS endpoints`,
+	},
+	{
+		Version: 2,
+		Name:    "create_peripherals",
+		Up: `CREATE TABLE IF NOT EXISTS peripherals (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL,
+			uuid TEXT NOT NULL,
+			enabled INTEGER NOT NULL DEFAULT 1
+		)`,
+		Down: `DROP TABLE IF EXISTS peripherals`,
+	},
+	{
+		Version: 3,
+		Name:    "add_endpoint_created_at",
+		Up:      `ALTER TABLE endpoints ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP`,
+		Down:    `ALTER TABLE endpoints DROP COLUMN created_at`,
+	},
+}
@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"database/sql"
+
+	"github.com/blent/beagle/src/server/storage/providers/sqlite/repositories"
+)
+
+// Provider is a configured storage backend: a connection plus the
+// repositories built on top of it. sqlite/postgres/mysql each implement
+// this against their own driver and migrations.
+type Provider interface {
+	GetDB() *sql.DB
+	GetEndpointRepository() *repositories.SQLiteEndpointRepository
+	Close() error
+}
@@ -0,0 +1,38 @@
+package mysql
+
+import "github.com/blent/beagle/server/storage/migrate"
+
+// MySQLMigrations mirrors migrate.SQLiteMigrations, written in
+// MySQL-flavoured DDL (AUTO_INCREMENT identity columns, backtick-quoted
+// identifiers handled by the dialect rather than here).
+var MySQLMigrations = []migrate.Migration{
+	{
+		Version: 1,
+		Name:    "create_endpoints",
+		Up: `CREATE TABLE IF NOT EXISTS endpoints (
+			id BIGINT AUTO_INCREMENT PRIMARY KEY,
+			name TEXT NOT NULL,
+			url TEXT NOT NULL,
+			method VARCHAR(16) NOT NULL,
+			headers TEXT
+		)`,
+		Down: `DROP TABLE IF EXISTS endpoints`,
+	},
+	{
+		Version: 2,
+		Name:    "create_peripherals",
+		Up: `CREATE TABLE IF NOT EXISTS peripherals (
+			id BIGINT AUTO_INCREMENT PRIMARY KEY,
+			name TEXT NOT NULL,
+			uuid VARCHAR(64) NOT NULL,
+			enabled TINYINT(1) NOT NULL DEFAULT 1
+		)`,
+		Down: `DROP TABLE IF EXISTS peripherals`,
+	},
+	{
+		Version: 3,
+		Name:    "add_endpoint_created_at",
+		Up:      `ALTER TABLE endpoints ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP`,
+		Down:    `ALTER TABLE endpoints DROP COLUMN created_at`,
+	},
+}
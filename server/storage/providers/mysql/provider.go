@@ -0,0 +1,78 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blent/beagle/server/storage/migrate"
+	storage "github.com/blent/beagle/src/server/storage"
+	"github.com/blent/beagle/src/server/storage/providers/sqlite/repositories"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// Provider is the MySQL-backed storage.Provider, built on go-sql-driver.
+// It reuses the sqlite-package repository and query builders behind
+// storage.MySQLDialect, so MySQL-specific SQL (e.g. ON DUPLICATE KEY
+// UPDATE instead of ON CONFLICT) lives in the dialect and query builder
+// rather than here.
+type Provider struct {
+	db        *sql.DB
+	watcher   *repositories.SQLiteWatcher
+	endpoints *repositories.SQLiteEndpointRepository
+}
+
+// OpenDB opens a mysql connection without running migrations or wiring a
+// watcher, for tooling (e.g. the migrate CLI subcommand) that needs raw
+// database access.
+func OpenDB(connectionString string) (*sql.DB, error) {
+	db, err := sql.Open("mysql", connectionString)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// NewMySQLProvider opens connectionString with the mysql driver, brings the
+// schema up to date and returns a ready-to-use Provider.
+func NewMySQLProvider(connectionString string) (*Provider, error) {
+	db, err := OpenDB(connectionString)
+
+	if err != nil {
+		return nil, err
+	}
+
+	runner := migrate.NewRunner(db, "mysql", MySQLMigrations)
+
+	if err := runner.Migrate(context.Background(), -1); err != nil {
+		return nil, err
+	}
+
+	watcher := repositories.NewSQLiteWatcher()
+	endpoints := repositories.NewSQLiteEndpointRepository("endpoints", db, watcher, storage.MySQLDialect, storage.DefaultQueryTimeout)
+
+	return &Provider{db, watcher, endpoints}, nil
+}
+
+func (p *Provider) GetDB() *sql.DB {
+	return p.db
+}
+
+func (p *Provider) GetWatcher() *repositories.SQLiteWatcher {
+	return p.watcher
+}
+
+func (p *Provider) GetEndpointRepository() *repositories.SQLiteEndpointRepository {
+	return p.endpoints
+}
+
+func (p *Provider) Close() error {
+	p.watcher.Close()
+	return p.db.Close()
+}
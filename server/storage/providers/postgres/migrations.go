@@ -0,0 +1,38 @@
+package postgres
+
+import "github.com/blent/beagle/server/storage/migrate"
+
+// PostgresMigrations mirrors migrate.SQLiteMigrations, written in
+// Postgres-flavoured DDL (SERIAL identity columns, BOOLEAN and TIMESTAMPTZ
+// column types).
+var PostgresMigrations = []migrate.Migration{
+	{
+		Version: 1,
+		Name:    "create_endpoints",
+		Up: `CREATE TABLE IF NOT EXISTS endpoints (
+			id SERIAL PRIMARY KEY,
+			name TEXT NOT NULL,
+			url TEXT NOT NULL,
+			method TEXT NOT NULL,
+			headers TEXT
+		)`,
+		Down: `DROP TABLE IF EXISTS endpoints`,
+	},
+	{
+		Version: 2,
+		Name:    "create_peripherals",
+		Up: `CREATE TABLE IF NOT EXISTS peripherals (
+			id SERIAL PRIMARY KEY,
+			name TEXT NOT NULL,
+			uuid TEXT NOT NULL,
+			enabled BOOLEAN NOT NULL DEFAULT TRUE
+		)`,
+		Down: `DROP TABLE IF EXISTS peripherals`,
+	},
+	{
+		Version: 3,
+		Name:    "add_endpoint_created_at",
+		Up:      `ALTER TABLE endpoints ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()`,
+		Down:    `ALTER TABLE endpoints DROP COLUMN created_at`,
+	},
+}
@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blent/beagle/server/storage/migrate"
+	storage "github.com/blent/beagle/src/server/storage"
+	"github.com/blent/beagle/src/server/storage/providers/sqlite/repositories"
+
+	_ "github.com/lib/pq"
+)
+
+// Provider is the Postgres-backed storage.Provider, built on lib/pq. It
+// reuses the sqlite-package repository and query builders behind
+// storage.PostgresDialect, so Postgres-specific behavior (e.g. reading
+// generated ids back via RETURNING instead of LastInsertId) lives in the
+// dialect and query builder rather than here.
+type Provider struct {
+	db        *sql.DB
+	watcher   *repositories.SQLiteWatcher
+	endpoints *repositories.SQLiteEndpointRepository
+}
+
+// OpenDB opens a postgres connection without running migrations or wiring
+// a watcher, for tooling (e.g. the migrate CLI subcommand) that needs raw
+// database access.
+func OpenDB(connectionString string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", connectionString)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// NewPostgresProvider opens connectionString with the postgres driver,
+// brings the schema up to date and returns a ready-to-use Provider.
+func NewPostgresProvider(connectionString string) (*Provider, error) {
+	db, err := OpenDB(connectionString)
+
+	if err != nil {
+		return nil, err
+	}
+
+	runner := migrate.NewRunner(db, "postgres", PostgresMigrations)
+
+	if err := runner.Migrate(context.Background(), -1); err != nil {
+		return nil, err
+	}
+
+	watcher := repositories.NewSQLiteWatcher()
+	endpoints := repositories.NewSQLiteEndpointRepository("endpoints", db, watcher, storage.PostgresDialect, storage.DefaultQueryTimeout)
+
+	return &Provider{db, watcher, endpoints}, nil
+}
+
+func (p *Provider) GetDB() *sql.DB {
+	return p.db
+}
+
+func (p *Provider) GetWatcher() *repositories.SQLiteWatcher {
+	return p.watcher
+}
+
+func (p *Provider) GetEndpointRepository() *repositories.SQLiteEndpointRepository {
+	return p.endpoints
+}
+
+func (p *Provider) Close() error {
+	p.watcher.Close()
+	return p.db.Close()
+}
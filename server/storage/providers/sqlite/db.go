@@ -0,0 +1,24 @@
+package sqlite
+
+import (
+	"database/sql"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// OpenDB opens a sqlite3 connection without running migrations or wiring a
+// watcher, for tooling (e.g. the migrate CLI subcommand) that needs raw
+// database access.
+func OpenDB(connectionString string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", connectionString)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
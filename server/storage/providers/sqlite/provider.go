@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/blent/beagle/server/storage/migrate"
+	storage "github.com/blent/beagle/src/server/storage"
+	"github.com/blent/beagle/src/server/storage/providers/sqlite/repositories"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Provider is the sqlite3-backed storage.Provider. It mirrors the
+// postgres/mysql providers: open the connection, bring the schema up to
+// date and wire a watcher that repositories publish change events to.
+type Provider struct {
+	db        *sql.DB
+	watcher   *repositories.SQLiteWatcher
+	endpoints *repositories.SQLiteEndpointRepository
+}
+
+// NewSQLiteProvider opens connectionString with the sqlite3 driver, brings
+// the schema up to date and returns a ready-to-use Provider.
+func NewSQLiteProvider(connectionString string) (*Provider, error) {
+	db, err := OpenDB(connectionString)
+
+	if err != nil {
+		return nil, err
+	}
+
+	runner := migrate.NewRunner(db, "sqlite3", migrate.SQLiteMigrations)
+
+	if err := runner.Migrate(context.Background(), -1); err != nil {
+		return nil, err
+	}
+
+	watcher := repositories.NewSQLiteWatcher()
+	endpoints := repositories.NewSQLiteEndpointRepository("endpoints", db, watcher, storage.SQLiteDialect, storage.DefaultQueryTimeout)
+
+	return &Provider{db, watcher, endpoints}, nil
+}
+
+func (p *Provider) GetDB() *sql.DB {
+	return p.db
+}
+
+func (p *Provider) GetWatcher() *repositories.SQLiteWatcher {
+	return p.watcher
+}
+
+func (p *Provider) GetEndpointRepository() *repositories.SQLiteEndpointRepository {
+	return p.endpoints
+}
+
+func (p *Provider) Close() error {
+	p.watcher.Close()
+	return p.db.Close()
+}
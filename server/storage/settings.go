@@ -0,0 +1,8 @@
+package storage
+
+// Settings configures which storage provider backs the application and how
+// to reach it.
+type Settings struct {
+	Provider         string
+	ConnectionString string
+}
@@ -0,0 +1,8 @@
+package storage
+
+import "time"
+
+// DefaultQueryTimeout is the query timeout every storage provider passes to
+// its repositories. It is not read from storage.Settings, so configuration
+// cannot change it.
+const DefaultQueryTimeout = 5 * time.Second
@@ -0,0 +1,79 @@
+package storage
+
+import "fmt"
+
+// Dialect abstracts the SQL differences between the supported storage
+// providers so query builders can stay driver-agnostic: parameter
+// placeholders, identifier quoting and pagination syntax all vary between
+// SQLite, Postgres and MySQL.
+type Dialect interface {
+	// Name is the provider name as used in Settings.Provider.
+	Name() string
+	// Placeholder returns the bound-parameter placeholder for the n-th
+	// argument of a query (1-indexed).
+	Placeholder(n int) string
+	// QuoteIdent quotes a table/column identifier for safe interpolation
+	// into a query string.
+	QuoteIdent(ident string) string
+	// LimitOffset renders the pagination clause for limit/offset values.
+	LimitOffset(limit, offset int) string
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string             { return "sqlite3" }
+func (sqliteDialect) Placeholder(n int) string { return "?" }
+
+func (sqliteDialect) QuoteIdent(ident string) string {
+	return fmt.Sprintf("%q", ident)
+}
+
+func (sqliteDialect) LimitOffset(limit, offset int) string {
+	return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string             { return "postgres" }
+func (postgresDialect) Placeholder(n int) string { return fmt.Sprintf("$%d", n) }
+
+func (postgresDialect) QuoteIdent(ident string) string {
+	return fmt.Sprintf("%q", ident)
+}
+
+func (postgresDialect) LimitOffset(limit, offset int) string {
+	return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+}
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string             { return "mysql" }
+func (mysqlDialect) Placeholder(n int) string { return "?" }
+
+func (mysqlDialect) QuoteIdent(ident string) string {
+	return fmt.Sprintf("`%s`", ident)
+}
+
+func (mysqlDialect) LimitOffset(limit, offset int) string {
+	return fmt.Sprintf("LIMIT %d, %d", offset, limit)
+}
+
+var (
+	SQLiteDialect   Dialect = sqliteDialect{}
+	PostgresDialect Dialect = postgresDialect{}
+	MySQLDialect    Dialect = mysqlDialect{}
+)
+
+// DialectFor resolves the Dialect matching a storage provider name.
+func DialectFor(provider string) (Dialect, error) {
+	switch provider {
+	case "sqlite3":
+		return SQLiteDialect, nil
+	case "postgres":
+		return PostgresDialect, nil
+	case "mysql":
+		return MySQLDialect, nil
+	default:
+		return nil, fmt.Errorf("unsupported storage dialect %q", provider)
+	}
+}
@@ -0,0 +1,83 @@
+package storage
+
+import "testing"
+
+func TestSQLiteDialect(t *testing.T) {
+	d := SQLiteDialect
+
+	if d.Name() != "sqlite3" {
+		t.Fatalf("expected name sqlite3, got %q", d.Name())
+	}
+
+	if d.Placeholder(2) != "?" {
+		t.Fatalf("expected placeholder ?, got %q", d.Placeholder(2))
+	}
+
+	if d.QuoteIdent("endpoints") != `"endpoints"` {
+		t.Fatalf("unexpected quoted ident %q", d.QuoteIdent("endpoints"))
+	}
+
+	if got, want := d.LimitOffset(10, 5), "LIMIT 10 OFFSET 5"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestPostgresDialect(t *testing.T) {
+	d := PostgresDialect
+
+	if d.Name() != "postgres" {
+		t.Fatalf("expected name postgres, got %q", d.Name())
+	}
+
+	if got, want := d.Placeholder(3), "$3"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	if got, want := d.LimitOffset(10, 5), "LIMIT 10 OFFSET 5"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestMySQLDialect(t *testing.T) {
+	d := MySQLDialect
+
+	if d.Name() != "mysql" {
+		t.Fatalf("expected name mysql, got %q", d.Name())
+	}
+
+	if d.Placeholder(1) != "?" {
+		t.Fatalf("expected placeholder ?, got %q", d.Placeholder(1))
+	}
+
+	if d.QuoteIdent("endpoints") != "`endpoints`" {
+		t.Fatalf("unexpected quoted ident %q", d.QuoteIdent("endpoints"))
+	}
+
+	if got, want := d.LimitOffset(10, 5), "LIMIT 5, 10"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestDialectFor(t *testing.T) {
+	cases := map[string]Dialect{
+		"sqlite3":  SQLiteDialect,
+		"postgres": PostgresDialect,
+		"mysql":    MySQLDialect,
+	}
+
+	for provider, want := range cases {
+		got, err := DialectFor(provider)
+
+		if err != nil {
+			t.Fatalf("DialectFor(%q) returned error: %v", provider, err)
+		}
+
+		if got != want {
+			t.Fatalf("DialectFor(%q) = %v, want %v", provider, got, want)
+		}
+	}
+
+	if _, err := DialectFor("oracle"); err == nil {
+		t.Fatal("expected error for unsupported dialect")
+	}
+}
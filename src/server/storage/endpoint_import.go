@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/blent/beagle/src/core/notification"
+	"github.com/pkg/errors"
+)
+
+// DecodeEndpointImport reads a JSON array of endpoints from r, for the
+// bulk import route to hand straight to an endpoint repository's
+// CreateMany (all-new payloads) or UpsertMany (payloads that may update
+// existing endpoints by id).
+func DecodeEndpointImport(r io.Reader) ([]*notification.Endpoint, error) {
+	var endpoints []*notification.Endpoint
+
+	if err := json.NewDecoder(r).Decode(&endpoints); err != nil {
+		return nil, errors.Wrap(err, "invalid endpoint import payload")
+	}
+
+	if len(endpoints) == 0 {
+		return nil, errors.New("import payload is empty")
+	}
+
+	return endpoints, nil
+}
@@ -0,0 +1,19 @@
+package storage
+
+// EndpointQuery describes how to filter and page through endpoints.
+//
+// Name is kept as convenience sugar for the common "find by glob" case and
+// is compiled into the same NameLike predicate a caller could build by
+// hand; Predicates holds any additional typed filters (NameLike,
+// URLPrefix, MethodIn, HeaderContains, CreatedBetween, ...).
+//
+// Pagination can use either Take/Skip (offset-based) or Take/After
+// (cursor-based, see EncodeCursor/DecodeCursor). After takes precedence
+// over Skip when both are set.
+type EndpointQuery struct {
+	Name       string
+	Predicates []Predicate
+	Take       int
+	Skip       int
+	After      string
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ParseEndpointQueryParams builds an EndpointQuery from HTTP query string
+// values, so the /registry/endpoints route can expose the typed predicates
+// and cursor pagination without each handler re-implementing the parsing.
+//
+// Recognized keys: name, url_prefix, method (repeatable), header (repeatable,
+// "key:value" with "*" as a wildcard), created_from/created_to (RFC3339),
+// take, skip, after.
+func ParseEndpointQueryParams(values url.Values) (*EndpointQuery, error) {
+	query := &EndpointQuery{
+		Name:  values.Get("name"),
+		After: values.Get("after"),
+	}
+
+	if prefix := values.Get("url_prefix"); prefix != "" {
+		query.Predicates = append(query.Predicates, URLPrefix(prefix))
+	}
+
+	if methods := values["method"]; len(methods) > 0 {
+		query.Predicates = append(query.Predicates, MethodIn(methods))
+	}
+
+	for _, header := range values["header"] {
+		key, value, ok := strings.Cut(header, ":")
+
+		if !ok {
+			return nil, errors.Errorf("invalid header filter %q, expected \"key:value\"", header)
+		}
+
+		query.Predicates = append(query.Predicates, HeaderContains(key, value))
+	}
+
+	from, to := values.Get("created_from"), values.Get("created_to")
+
+	if from != "" || to != "" {
+		fromTime, toTime, err := parseCreatedRange(from, to)
+
+		if err != nil {
+			return nil, err
+		}
+
+		query.Predicates = append(query.Predicates, CreatedBetween(fromTime, toTime))
+	}
+
+	if take := values.Get("take"); take != "" {
+		n, err := strconv.Atoi(take)
+
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid take")
+		}
+
+		query.Take = n
+	}
+
+	if skip := values.Get("skip"); skip != "" {
+		n, err := strconv.Atoi(skip)
+
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid skip")
+		}
+
+		query.Skip = n
+	}
+
+	return query, nil
+}
+
+func parseCreatedRange(from, to string) (time.Time, time.Time, error) {
+	var fromTime, toTime time.Time
+	var err error
+
+	if from != "" {
+		fromTime, err = time.Parse(time.RFC3339, from)
+
+		if err != nil {
+			return fromTime, toTime, errors.Wrap(err, "invalid created_from")
+		}
+	}
+
+	if to != "" {
+		toTime, err = time.Parse(time.RFC3339, to)
+
+		if err != nil {
+			return fromTime, toTime, errors.Wrap(err, "invalid created_to")
+		}
+	} else {
+		toTime = time.Now()
+	}
+
+	return fromTime, toTime, nil
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseEndpointQueryParamsBasic(t *testing.T) {
+	values := url.Values{
+		"name":  []string{"foo"},
+		"take":  []string{"10"},
+		"skip":  []string{"5"},
+		"after": []string{"abc"},
+	}
+
+	query, err := ParseEndpointQueryParams(values)
+
+	if err != nil {
+		t.Fatalf("ParseEndpointQueryParams failed: %v", err)
+	}
+
+	if query.Name != "foo" {
+		t.Errorf("expected name foo, got %q", query.Name)
+	}
+
+	if query.Take != 10 {
+		t.Errorf("expected take 10, got %d", query.Take)
+	}
+
+	if query.Skip != 5 {
+		t.Errorf("expected skip 5, got %d", query.Skip)
+	}
+
+	if query.After != "abc" {
+		t.Errorf("expected after abc, got %q", query.After)
+	}
+}
+
+func TestParseEndpointQueryParamsPredicates(t *testing.T) {
+	values := url.Values{
+		"url_prefix": []string{"/api"},
+		"method":     []string{"GET", "POST"},
+		"header":     []string{"Authorization:Bearer*"},
+	}
+
+	query, err := ParseEndpointQueryParams(values)
+
+	if err != nil {
+		t.Fatalf("ParseEndpointQueryParams failed: %v", err)
+	}
+
+	if len(query.Predicates) != 3 {
+		t.Fatalf("expected 3 predicates, got %d", len(query.Predicates))
+	}
+}
+
+func TestParseEndpointQueryParamsInvalidHeader(t *testing.T) {
+	values := url.Values{"header": []string{"no-colon-here"}}
+
+	if _, err := ParseEndpointQueryParams(values); err == nil {
+		t.Fatal("expected error for malformed header filter")
+	}
+}
+
+func TestParseEndpointQueryParamsInvalidTake(t *testing.T) {
+	values := url.Values{"take": []string{"not-a-number"}}
+
+	if _, err := ParseEndpointQueryParams(values); err == nil {
+		t.Fatal("expected error for invalid take")
+	}
+}
+
+func TestParseEndpointQueryParamsInvalidCreatedRange(t *testing.T) {
+	values := url.Values{"created_from": []string{"not-a-date"}}
+
+	if _, err := ParseEndpointQueryParams(values); err == nil {
+		t.Fatal("expected error for invalid created_from")
+	}
+}
+
+func TestParseEndpointQueryParamsCreatedRange(t *testing.T) {
+	values := url.Values{
+		"created_from": []string{"2026-01-01T00:00:00Z"},
+		"created_to":   []string{"2026-01-31T00:00:00Z"},
+	}
+
+	query, err := ParseEndpointQueryParams(values)
+
+	if err != nil {
+		t.Fatalf("ParseEndpointQueryParams failed: %v", err)
+	}
+
+	if len(query.Predicates) != 1 {
+		t.Fatalf("expected 1 predicate, got %d", len(query.Predicates))
+	}
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Predicate is a single parameterized filter condition that compiles into
+// a SQL fragment plus its bound arguments. next yields the dialect's
+// placeholder for the next positional argument (e.g. "?" or "$3").
+type Predicate interface {
+	Compile(next func() string) (string, []interface{})
+}
+
+type predicateFunc func(next func() string) (string, []interface{})
+
+func (f predicateFunc) Compile(next func() string) (string, []interface{}) {
+	return f(next)
+}
+
+// NameLike matches the endpoint name against pattern, which may use a
+// leading and/or trailing "*" as a glob wildcard (e.g. "foo*", "*foo",
+// "*foo*") or be an exact match when no wildcard is present.
+func NameLike(pattern string) Predicate {
+	return predicateFunc(func(next func() string) (string, []interface{}) {
+		startsWith := strings.HasPrefix(pattern, "*")
+		endsWith := strings.HasSuffix(pattern, "*")
+
+		if !startsWith && !endsWith {
+			return "name = " + next(), []interface{}{pattern}
+		}
+
+		arg := strings.Replace(pattern, "*", "", -1)
+
+		switch {
+		case startsWith && endsWith:
+			arg = "%" + arg + "%"
+		case endsWith:
+			arg = arg + "%"
+		default:
+			arg = "%" + arg
+		}
+
+		return "name LIKE " + next(), []interface{}{arg}
+	})
+}
+
+// URLPrefix matches endpoints whose URL starts with prefix.
+func URLPrefix(prefix string) Predicate {
+	return predicateFunc(func(next func() string) (string, []interface{}) {
+		return "url LIKE " + next(), []interface{}{prefix + "%"}
+	})
+}
+
+// MethodIn matches endpoints whose HTTP method is one of methods.
+func MethodIn(methods []string) Predicate {
+	return predicateFunc(func(next func() string) (string, []interface{}) {
+		placeholders := make([]string, len(methods))
+		args := make([]interface{}, len(methods))
+
+		for i, method := range methods {
+			placeholders[i] = next()
+			args[i] = method
+		}
+
+		return "method IN (" + strings.Join(placeholders, ", ") + ")", args
+	})
+}
+
+// HeaderContains matches endpoints whose serialized headers contain key
+// followed by a value matching valueRegex. Headers are stored as a single
+// text blob, so the match is a substring LIKE rather than a true regex;
+// valueRegex is treated as a literal substring with "*" usable as a
+// wildcard, consistent with NameLike.
+func HeaderContains(key, valueRegex string) Predicate {
+	return predicateFunc(func(next func() string) (string, []interface{}) {
+		value := strings.Replace(valueRegex, "*", "%", -1)
+		return "headers LIKE " + next(), []interface{}{"%" + key + "%" + value + "%"}
+	})
+}
+
+// CreatedBetween matches endpoints created within [from, to].
+func CreatedBetween(from, to time.Time) Predicate {
+	return predicateFunc(func(next func() string) (string, []interface{}) {
+		return fmt.Sprintf("created_at BETWEEN %s AND %s", next(), next()), []interface{}{from, to}
+	})
+}
+
+// IDAfter matches endpoints with an id greater than id; it backs cursor
+// pagination via EndpointQuery.After rather than being constructed by
+// callers directly.
+func IDAfter(id uint64) Predicate {
+	return predicateFunc(func(next func() string) (string, []interface{}) {
+		return "id > " + next(), []interface{}{id}
+	})
+}
+
+// EncodeCursor produces the opaque pagination token for the last row id of
+// a page.
+func EncodeCursor(id uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d", id)))
+}
+
+// DecodeCursor reverses EncodeCursor.
+func DecodeCursor(cursor string) (uint64, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+
+	if err != nil {
+		return 0, err
+	}
+
+	var id uint64
+
+	if _, err := fmt.Sscanf(string(decoded), "%d", &id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
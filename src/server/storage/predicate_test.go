@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func compile(p Predicate) (string, []interface{}) {
+	n := 0
+	next := func() string {
+		n++
+		return "$" + strconv.Itoa(n)
+	}
+
+	return p.Compile(next)
+}
+
+func TestNameLike(t *testing.T) {
+	cases := []struct {
+		pattern  string
+		wantSQL  string
+		wantArgs []interface{}
+	}{
+		{"foo", "name = $1", []interface{}{"foo"}},
+		{"foo*", "name LIKE $1", []interface{}{"foo%"}},
+		{"*foo", "name LIKE $1", []interface{}{"%foo"}},
+		{"*foo*", "name LIKE $1", []interface{}{"%foo%"}},
+	}
+
+	for _, c := range cases {
+		sql, args := compile(NameLike(c.pattern))
+
+		if sql != c.wantSQL {
+			t.Errorf("NameLike(%q) sql = %q, want %q", c.pattern, sql, c.wantSQL)
+		}
+
+		if len(args) != 1 || args[0] != c.wantArgs[0] {
+			t.Errorf("NameLike(%q) args = %v, want %v", c.pattern, args, c.wantArgs)
+		}
+	}
+}
+
+func TestURLPrefix(t *testing.T) {
+	sql, args := compile(URLPrefix("/api"))
+
+	if sql != "url LIKE $1" {
+		t.Fatalf("unexpected sql %q", sql)
+	}
+
+	if len(args) != 1 || args[0] != "/api%" {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
+
+func TestMethodIn(t *testing.T) {
+	sql, args := compile(MethodIn([]string{"GET", "POST"}))
+
+	if sql != "method IN ($1, $2)" {
+		t.Fatalf("unexpected sql %q", sql)
+	}
+
+	if len(args) != 2 || args[0] != "GET" || args[1] != "POST" {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
+
+func TestHeaderContains(t *testing.T) {
+	sql, args := compile(HeaderContains("Authorization", "Bearer*"))
+
+	if sql != "headers LIKE $1" {
+		t.Fatalf("unexpected sql %q", sql)
+	}
+
+	if len(args) != 1 || args[0] != "%Authorization%Bearer%%" {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
+
+func TestCreatedBetween(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	sql, args := compile(CreatedBetween(from, to))
+
+	if sql != "created_at BETWEEN $1 AND $2" {
+		t.Fatalf("unexpected sql %q", sql)
+	}
+
+	if len(args) != 2 || args[0] != from || args[1] != to {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
+
+func TestIDAfter(t *testing.T) {
+	sql, args := compile(IDAfter(42))
+
+	if sql != "id > $1" {
+		t.Fatalf("unexpected sql %q", sql)
+	}
+
+	if len(args) != 1 || args[0] != uint64(42) {
+		t.Fatalf("unexpected args %v", args)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := EncodeCursor(123456)
+
+	id, err := DecodeCursor(cursor)
+
+	if err != nil {
+		t.Fatalf("DecodeCursor failed: %v", err)
+	}
+
+	if id != 123456 {
+		t.Fatalf("expected 123456, got %d", id)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	if _, err := DecodeCursor("not valid base64!!"); err == nil {
+		t.Fatal("expected error for invalid cursor")
+	}
+}
@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"github.com/blent/beagle/src/core/notification"
@@ -8,43 +9,62 @@ import (
 	"github.com/blent/beagle/src/server/storage/providers/sqlite/repositories/mapping"
 	"github.com/blent/beagle/src/server/utils"
 	"github.com/pkg/errors"
-	"strings"
-)
-
-const (
-	endpointSelectQuery       = "SELECT id, name, url, method, headers FROM %s"
-	endpointInsertQuery       = "INSERT INTO %s (name, url, method, headers) VALUES %s"
-	endpointInsertValuesQuery = "(?, ?, ?, ?)"
-	endpointUpdateQuery       = "UPDATE %s SET name=?, url=?, method=?, headers=? WHERE id=?"
-	endpointDeleteQuery       = "DELETE FROM %s"
-	endpointCountQuery        = "SELECT COUNT(id) from %s"
+	"time"
 )
 
 type (
 	SQLiteEndpointRepository struct {
-		tableName string
-		db        *sql.DB
+		tableName    string
+		db           *sql.DB
+		watcher      storage.Producer
+		query        *endpointQueryBuilder
+		queryTimeout time.Duration
 	}
 )
 
-func NewSQLiteEndpointRepository(tableName string, db *sql.DB) *SQLiteEndpointRepository {
-	return &SQLiteEndpointRepository{tableName, db}
+func NewSQLiteEndpointRepository(tableName string, db *sql.DB, watcher storage.Producer, dialect storage.Dialect, queryTimeout time.Duration) *SQLiteEndpointRepository {
+	return &SQLiteEndpointRepository{
+		tableName,
+		db,
+		watcher,
+		newEndpointQueryBuilder(dialect, tableName),
+		queryTimeout,
+	}
+}
+
+func (r *SQLiteEndpointRepository) publish(op storage.Op, id uint64, payload interface{}) {
+	if r.watcher == nil {
+		return
+	}
+
+	r.watcher.Publish(storage.Event{
+		EntityKind: storage.EntityKindEndpoint,
+		Op:         op,
+		ID:         id,
+		Payload:    payload,
+	})
 }
 
-func (r *SQLiteEndpointRepository) Get(id uint64) (*notification.Endpoint, error) {
+// withTimeout applies the repository's configured query timeout when ctx
+// doesn't already carry a deadline, so a caller that forgets to set one
+// can't hang a query indefinitely.
+func (r *SQLiteEndpointRepository) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, hasDeadline := ctx.Deadline(); hasDeadline || r.queryTimeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, r.queryTimeout)
+}
+
+func (r *SQLiteEndpointRepository) Get(ctx context.Context, id uint64) (*notification.Endpoint, error) {
 	if id == 0 {
 		return nil, errors.New("id must be greater than 0")
 	}
 
-	stmt, err := r.db.Prepare(
-		fmt.Sprintf(
-			"%s WHERE id=? LIMIT 1",
-			fmt.Sprintf(
-				endpointSelectQuery,
-				r.tableName,
-			),
-		),
-	)
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	stmt, err := r.db.PrepareContext(ctx, r.query.SelectByID())
 
 	if err != nil {
 		return nil, err
@@ -52,75 +72,98 @@ func (r *SQLiteEndpointRepository) Get(id uint64) (*notification.Endpoint, error
 
 	defer stmt.Close()
 
-	return mapping.ToEndpoint(stmt.QueryRow(id))
+	return mapping.ToEndpoint(stmt.QueryRowContext(ctx, id))
 }
 
-func (r *SQLiteEndpointRepository) Find(query *storage.EndpointQuery) ([]*notification.Endpoint, error) {
-	args := make([]interface{}, 0, 5)
-	findQuery := fmt.Sprintf(endpointSelectQuery, r.tableName)
+// Find returns endpoints matching query, along with a cursor for the next
+// page when query.Take is set and more rows remain. The cursor is empty
+// once the caller has reached the last page.
+func (r *SQLiteEndpointRepository) Find(ctx context.Context, query *storage.EndpointQuery) ([]*notification.Endpoint, string, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var predicates []storage.Predicate
+	take, skip := 0, 0
+	after := ""
 
 	if query != nil {
 		if query.Name != "" {
-			findQuery += " WHERE"
-
-			startsWith := strings.HasPrefix(query.Name, "*")
-			endsWith := strings.HasSuffix(query.Name, "*")
-			arg := query.Name
-
-			if startsWith || endsWith {
-				arg = strings.Replace(arg, "*", "", -1)
-
-				if startsWith && endsWith {
-					arg = "%" + arg + "%"
-				} else if endsWith {
-					arg = arg + "%"
-				} else {
-					arg = "%" + arg
-				}
-
-				findQuery += " name LIKE ?"
-			} else {
-				findQuery += " name = ?"
+			predicates = append(predicates, storage.NameLike(query.Name))
+		}
+
+		predicates = append(predicates, query.Predicates...)
+
+		take, skip, after = query.Take, query.Skip, query.After
+
+		if after != "" {
+			afterID, err := storage.DecodeCursor(after)
+
+			if err != nil {
+				return nil, "", errors.Wrap(err, "invalid cursor")
 			}
 
-			args = append(args, arg)
+			predicates = append(predicates, storage.IDAfter(afterID))
 		}
+	}
+
+	where, args := r.query.Where(predicates)
+
+	findQuery := r.query.Select()
 
-		findQuery += " ORDER BY id"
+	if where != "" {
+		findQuery += " WHERE " + where
+	}
+
+	findQuery += " ORDER BY id"
 
+	fetch := take
 
-		if query.Take > 0 {
-			findQuery += " LIMIT ? OFFSET ?"
+	if take > 0 {
+		// Fetch one extra row to tell whether another page follows.
+		fetch = take + 1
 
-			args = append(args, query.Take, query.Skip)
+		if after != "" {
+			findQuery += " " + r.query.Limit(fetch)
+		} else {
+			findQuery += " " + r.query.dialect.LimitOffset(fetch, skip)
 		}
-	} else {
-		findQuery += " ORDER BY id"
 	}
 
-	fmt.Println(findQuery)
-
-	stmt, err := r.db.Prepare(findQuery)
+	stmt, err := r.db.PrepareContext(ctx, findQuery)
 
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	defer stmt.Close()
 
-	rows, err := stmt.Query(args...)
+	rows, err := stmt.QueryContext(ctx, args...)
 
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	endpoints, err := mapping.ToEndpoints(rows, fetch)
+
+	if err != nil {
+		return nil, "", err
 	}
 
-	return mapping.ToEndpoints(rows, query.Take)
+	nextCursor := ""
+
+	if take > 0 && len(endpoints) > take {
+		endpoints = endpoints[:take]
+		nextCursor = storage.EncodeCursor(endpoints[len(endpoints)-1].Id)
+	}
+
+	return endpoints, nextCursor, nil
 }
 
-func (r *SQLiteEndpointRepository) Count() (uint64, error) {
-	queryStmt := fmt.Sprintf(endpointCountQuery, r.tableName)
+func (r *SQLiteEndpointRepository) Count(ctx context.Context) (uint64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 
-	stmt, err := r.db.Prepare(queryStmt)
+	stmt, err := r.db.PrepareContext(ctx, r.query.Count())
 
 	if err != nil {
 		return 0, err
@@ -128,7 +171,7 @@ func (r *SQLiteEndpointRepository) Count() (uint64, error) {
 
 	defer stmt.Close()
 
-	row := stmt.QueryRow()
+	row := stmt.QueryRowContext(ctx)
 
 	var count uint64
 
@@ -141,7 +184,7 @@ func (r *SQLiteEndpointRepository) Count() (uint64, error) {
 	return count, nil
 }
 
-func (r *SQLiteEndpointRepository) Create(endpoint *notification.Endpoint, tx *sql.Tx) (uint64, error) {
+func (r *SQLiteEndpointRepository) Create(ctx context.Context, endpoint *notification.Endpoint, tx *sql.Tx) (uint64, error) {
 	if endpoint == nil {
 		return 0, errors.New("endpoint missed")
 	}
@@ -153,28 +196,34 @@ func (r *SQLiteEndpointRepository) Create(endpoint *notification.Endpoint, tx *s
 		return 0, errors.New("endpoint already created")
 	}
 
-	tx, closeTx, err := storage.TryToBegin(r.db, tx)
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, closeTx, err := storage.TryToBeginContext(ctx, r.db, tx)
 
 	if err != nil {
 		return 0, err
 	}
 
-	stmt, err := tx.Prepare(
-		fmt.Sprintf(endpointInsertQuery, r.tableName, endpointInsertValuesQuery),
-	)
+	stmt, err := tx.PrepareContext(ctx, r.query.Insert())
 
 	if err != nil {
 		return 0, storage.TryToRollback(tx, err, closeTx)
 	}
 
-	res, err := stmt.Exec(endpoint.Name, endpoint.Url, endpoint.Method, endpoint.Headers)
+	// lib/pq doesn't implement LastInsertId(), so Postgres reads the id
+	// back via the RETURNING clause Insert() appends for it instead.
+	if r.query.dialect.Name() == "postgres" {
+		err = stmt.QueryRowContext(ctx, endpoint.Name, endpoint.Url, endpoint.Method, endpoint.Headers).Scan(&id)
+	} else {
+		var res sql.Result
+		res, err = stmt.ExecContext(ctx, endpoint.Name, endpoint.Url, endpoint.Method, endpoint.Headers)
 
-	if err != nil {
-		return 0, storage.TryToRollback(tx, err, closeTx)
+		if err == nil {
+			id, err = res.LastInsertId()
+		}
 	}
 
-	id, err = res.LastInsertId()
-
 	if err != nil {
 		return 0, storage.TryToRollback(tx, err, closeTx)
 	}
@@ -185,10 +234,201 @@ func (r *SQLiteEndpointRepository) Create(endpoint *notification.Endpoint, tx *s
 		return 0, err
 	}
 
+	endpoint.Id = uint64(id)
+
+	r.publish(storage.OpCreate, uint64(id), endpoint)
+
 	return uint64(id), nil
 }
 
-func (r *SQLiteEndpointRepository) Update(endpoint *notification.Endpoint, tx *sql.Tx) error {
+// CreateMany batch-inserts endpoints using a single multi-row INSERT
+// within one transaction, returning the assigned ids in the same order as
+// endpoints. It is meant for provisioning large fleets from config files
+// or restoring backups, where a one-row-per-Exec loop is too slow.
+func (r *SQLiteEndpointRepository) CreateMany(ctx context.Context, endpoints []*notification.Endpoint, tx *sql.Tx) ([]uint64, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("passed empty list of endpoints")
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, closeTx, err := storage.TryToBeginContext(ctx, r.db, tx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]interface{}, 0, len(endpoints)*4)
+
+	for _, endpoint := range endpoints {
+		if endpoint.Id > 0 {
+			return nil, storage.TryToRollback(tx, errors.New("endpoint already created"), closeTx)
+		}
+
+		args = append(args, endpoint.Name, endpoint.Url, endpoint.Method, endpoint.Headers)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, r.query.InsertMany(len(endpoints)))
+
+	if err != nil {
+		return nil, storage.TryToRollback(tx, err, closeTx)
+	}
+
+	var ids []uint64
+
+	switch r.query.dialect.Name() {
+	case "postgres":
+		// lib/pq doesn't implement LastInsertId(); InsertMany() appends
+		// RETURNING id for Postgres, so read one id back per inserted row.
+		rows, queryErr := stmt.QueryContext(ctx, args...)
+
+		if queryErr != nil {
+			return nil, storage.TryToRollback(tx, queryErr, closeTx)
+		}
+
+		ids, err = scanIds(rows, len(endpoints))
+	case "mysql":
+		// go-sql-driver's LastInsertId() on a multi-row INSERT reports the
+		// FIRST auto-increment value assigned, not the last, per
+		// mysql_insert_id() semantics.
+		var res sql.Result
+		res, err = stmt.ExecContext(ctx, args...)
+
+		if err == nil {
+			var firstID int64
+			firstID, err = res.LastInsertId()
+			ids = sequentialIds(uint64(firstID), len(endpoints))
+		}
+	default:
+		// sqlite assigns contiguous rowids to a multi-row INSERT, with
+		// LastInsertId() reporting the final one.
+		var res sql.Result
+		res, err = stmt.ExecContext(ctx, args...)
+
+		if err == nil {
+			var lastID int64
+			lastID, err = res.LastInsertId()
+			firstID := uint64(lastID) - uint64(len(endpoints)) + 1
+			ids = sequentialIds(firstID, len(endpoints))
+		}
+	}
+
+	if err != nil {
+		return nil, storage.TryToRollback(tx, err, closeTx)
+	}
+
+	if err := storage.TryToCommit(tx, closeTx); err != nil {
+		return nil, err
+	}
+
+	for i, endpoint := range endpoints {
+		endpoint.Id = ids[i]
+		r.publish(storage.OpCreate, ids[i], endpoint)
+	}
+
+	return ids, nil
+}
+
+// sequentialIds builds the n contiguous ids starting at first, for drivers
+// whose multi-row insert only reports a single anchor id.
+func sequentialIds(first uint64, n int) []uint64 {
+	ids := make([]uint64, n)
+
+	for i := range ids {
+		ids[i] = first + uint64(i)
+	}
+
+	return ids
+}
+
+// scanIds reads n ids off the RETURNING id rows of a multi-row insert, in
+// the order Postgres returns them (the order rows were inserted).
+func scanIds(rows *sql.Rows, n int) ([]uint64, error) {
+	defer rows.Close()
+
+	ids := make([]uint64, 0, n)
+
+	for rows.Next() {
+		var id uint64
+
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) != n {
+		return nil, errors.Errorf("expected %d returned ids, got %d", n, len(ids))
+	}
+
+	return ids, nil
+}
+
+// UpsertMany batch-inserts or -updates endpoints (keyed by id) using a
+// single multi-row INSERT ... ON CONFLICT within one transaction.
+// Endpoints with Id == 0 are always treated as new rows.
+func (r *SQLiteEndpointRepository) UpsertMany(ctx context.Context, endpoints []*notification.Endpoint, tx *sql.Tx) error {
+	if len(endpoints) == 0 {
+		return errors.New("passed empty list of endpoints")
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, closeTx, err := storage.TryToBeginContext(ctx, r.db, tx)
+
+	if err != nil {
+		return err
+	}
+
+	args := make([]interface{}, 0, len(endpoints)*5)
+
+	for _, endpoint := range endpoints {
+		// A NULL id lets the database assign one; binding 0 would store the
+		// row at id 0 instead.
+		var id interface{}
+
+		if endpoint.Id > 0 {
+			id = endpoint.Id
+		}
+
+		args = append(args, id, endpoint.Name, endpoint.Url, endpoint.Method, endpoint.Headers)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, r.query.UpsertMany(len(endpoints)))
+
+	if err != nil {
+		return storage.TryToRollback(tx, err, closeTx)
+	}
+
+	if _, err := stmt.ExecContext(ctx, args...); err != nil {
+		return storage.TryToRollback(tx, err, closeTx)
+	}
+
+	if err := storage.TryToCommit(tx, closeTx); err != nil {
+		return err
+	}
+
+	for _, endpoint := range endpoints {
+		op := storage.OpUpdate
+
+		if endpoint.Id == 0 {
+			op = storage.OpCreate
+		}
+
+		r.publish(op, endpoint.Id, endpoint)
+	}
+
+	return nil
+}
+
+func (r *SQLiteEndpointRepository) Update(ctx context.Context, endpoint *notification.Endpoint, tx *sql.Tx) error {
 	if endpoint == nil {
 		return errors.New("endpoint missed")
 	}
@@ -199,79 +439,94 @@ func (r *SQLiteEndpointRepository) Update(endpoint *notification.Endpoint, tx *s
 		return errors.New("endpoint not created yet")
 	}
 
-	tx, closeTx, err := storage.TryToBegin(r.db, tx)
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, closeTx, err := storage.TryToBeginContext(ctx, r.db, tx)
 
 	if err != nil {
 		return err
 	}
 
-	stmt, err := tx.Prepare(
-		fmt.Sprintf(endpointUpdateQuery, r.tableName),
-	)
+	stmt, err := tx.PrepareContext(ctx, r.query.Update())
 
 	if err != nil {
 		return storage.TryToRollback(tx, err, closeTx)
 	}
 
-	_, err = stmt.Exec(endpoint.Name, endpoint.Url, endpoint.Method, endpoint.Headers, endpoint.Id)
+	_, err = stmt.ExecContext(ctx, endpoint.Name, endpoint.Url, endpoint.Method, endpoint.Headers, endpoint.Id)
 
 	if err != nil {
 		return storage.TryToRollback(tx, err, closeTx)
 	}
 
-	return storage.TryToCommit(tx, closeTx)
+	if err := storage.TryToCommit(tx, closeTx); err != nil {
+		return err
+	}
+
+	r.publish(storage.OpUpdate, endpoint.Id, endpoint)
+
+	return nil
 }
 
-func (r *SQLiteEndpointRepository) Delete(id uint64, tx *sql.Tx) error {
+func (r *SQLiteEndpointRepository) Delete(ctx context.Context, id uint64, tx *sql.Tx) error {
 	if id == 0 {
 		return errors.New("id must be greater than 0")
 	}
 
 	var err error
 
-	tx, closeTx, err := storage.TryToBegin(r.db, tx)
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, closeTx, err := storage.TryToBeginContext(ctx, r.db, tx)
 
 	if err != nil {
 		return err
 	}
 
-	stmt, err := tx.Prepare(
-		fmt.Sprintf(
-			"%s WHERE id=?",
-			fmt.Sprintf(endpointDeleteQuery, r.tableName),
-		),
-	)
+	stmt, err := tx.PrepareContext(ctx, r.query.DeleteByID())
 
 	if err != nil {
 		return storage.TryToRollback(tx, err, closeTx)
 	}
 
-	_, err = stmt.Exec(id)
+	_, err = stmt.ExecContext(ctx, id)
 
 	if err != nil {
 		return storage.TryToRollback(tx, err, closeTx)
 	}
 
-	return storage.TryToCommit(tx, closeTx)
+	if err := storage.TryToCommit(tx, closeTx); err != nil {
+		return err
+	}
+
+	r.publish(storage.OpDelete, id, nil)
+
+	return nil
 }
 
-func (r *SQLiteEndpointRepository) DeleteMany(ids []uint64, tx *sql.Tx) error {
+func (r *SQLiteEndpointRepository) DeleteMany(ctx context.Context, ids []uint64, tx *sql.Tx) error {
 	if len(ids) == 0 {
 		return errors.New("passed empty list of ids")
 	}
 
 	var err error
 
-	tx, closeTx, err := storage.TryToBegin(r.db, tx)
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	tx, closeTx, err := storage.TryToBeginContext(ctx, r.db, tx)
 
 	if err != nil {
 		return err
 	}
 
-	stmt, err := tx.Prepare(
+	stmt, err := tx.PrepareContext(
+		ctx,
 		fmt.Sprintf(
 			"%s WHERE id IN (%s)",
-			fmt.Sprintf(endpointDeleteQuery, r.tableName),
+			r.query.Delete(),
 			utils.JoinUintSlice(ids, ", "),
 		),
 	)
@@ -280,11 +535,19 @@ func (r *SQLiteEndpointRepository) DeleteMany(ids []uint64, tx *sql.Tx) error {
 		return storage.TryToRollback(tx, err, closeTx)
 	}
 
-	_, err = stmt.Exec()
+	_, err = stmt.ExecContext(ctx)
 
 	if err != nil {
 		return storage.TryToRollback(tx, err, closeTx)
 	}
 
-	return storage.TryToCommit(tx, closeTx)
+	if err := storage.TryToCommit(tx, closeTx); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		r.publish(storage.OpDelete, id, nil)
+	}
+
+	return nil
 }
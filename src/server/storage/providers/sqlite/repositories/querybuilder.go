@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blent/beagle/src/server/storage"
+)
+
+var endpointColumns = []string{"id", "name", "url", "method", "headers"}
+
+// endpointQueryBuilder renders the CRUD statements for the endpoints table
+// for a given storage.Dialect, keeping placeholder style, identifier
+// quoting and pagination syntax out of the repository methods themselves.
+type endpointQueryBuilder struct {
+	dialect   storage.Dialect
+	tableName string
+}
+
+func newEndpointQueryBuilder(dialect storage.Dialect, tableName string) *endpointQueryBuilder {
+	return &endpointQueryBuilder{dialect, tableName}
+}
+
+func (b *endpointQueryBuilder) table() string {
+	return b.dialect.QuoteIdent(b.tableName)
+}
+
+func (b *endpointQueryBuilder) Select() string {
+	return fmt.Sprintf("SELECT %s FROM %s", strings.Join(endpointColumns, ", "), b.table())
+}
+
+func (b *endpointQueryBuilder) SelectByID() string {
+	return fmt.Sprintf("%s WHERE id=%s LIMIT 1", b.Select(), b.dialect.Placeholder(1))
+}
+
+func (b *endpointQueryBuilder) Count() string {
+	return fmt.Sprintf("SELECT COUNT(id) FROM %s", b.table())
+}
+
+func (b *endpointQueryBuilder) Insert() string {
+	query := fmt.Sprintf(
+		"INSERT INTO %s (name, url, method, headers) VALUES (%s, %s, %s, %s)",
+		b.table(),
+		b.dialect.Placeholder(1),
+		b.dialect.Placeholder(2),
+		b.dialect.Placeholder(3),
+		b.dialect.Placeholder(4),
+	)
+
+	if b.dialect.Name() == "postgres" {
+		query += " RETURNING id"
+	}
+
+	return query
+}
+
+// InsertMany renders a single multi-row INSERT for n endpoints. For
+// Postgres it appends RETURNING id so the caller can read back the ids
+// assigned to every row, since lib/pq doesn't support LastInsertId().
+func (b *endpointQueryBuilder) InsertMany(n int) string {
+	rows := make([]string, n)
+
+	for i := 0; i < n; i++ {
+		base := i * 4
+		rows[i] = fmt.Sprintf(
+			"(%s, %s, %s, %s)",
+			b.dialect.Placeholder(base+1),
+			b.dialect.Placeholder(base+2),
+			b.dialect.Placeholder(base+3),
+			b.dialect.Placeholder(base+4),
+		)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (name, url, method, headers) VALUES %s", b.table(), strings.Join(rows, ", "))
+
+	if b.dialect.Name() == "postgres" {
+		query += " RETURNING id"
+	}
+
+	return query
+}
+
+// UpsertMany renders a single multi-row upsert for n endpoints, each
+// identified by id: new ids are inserted, existing ones have their
+// name/url/method/headers replaced. MySQL has no ON CONFLICT syntax, so it
+// gets its own ON DUPLICATE KEY UPDATE clause.
+func (b *endpointQueryBuilder) UpsertMany(n int) string {
+	rows := make([]string, n)
+
+	for i := 0; i < n; i++ {
+		base := i * 5
+		rows[i] = fmt.Sprintf(
+			"(%s, %s, %s, %s, %s)",
+			b.dialect.Placeholder(base+1),
+			b.dialect.Placeholder(base+2),
+			b.dialect.Placeholder(base+3),
+			b.dialect.Placeholder(base+4),
+			b.dialect.Placeholder(base+5),
+		)
+	}
+
+	values := fmt.Sprintf(
+		"INSERT INTO %s (id, name, url, method, headers) VALUES %s",
+		b.table(),
+		strings.Join(rows, ", "),
+	)
+
+	if b.dialect.Name() == "mysql" {
+		return values + " ON DUPLICATE KEY UPDATE name=VALUES(name), url=VALUES(url), method=VALUES(method), headers=VALUES(headers)"
+	}
+
+	return values + " ON CONFLICT(id) DO UPDATE SET name=excluded.name, url=excluded.url, method=excluded.method, headers=excluded.headers"
+}
+
+func (b *endpointQueryBuilder) Update() string {
+	return fmt.Sprintf(
+		"UPDATE %s SET name=%s, url=%s, method=%s, headers=%s WHERE id=%s",
+		b.table(),
+		b.dialect.Placeholder(1),
+		b.dialect.Placeholder(2),
+		b.dialect.Placeholder(3),
+		b.dialect.Placeholder(4),
+		b.dialect.Placeholder(5),
+	)
+}
+
+func (b *endpointQueryBuilder) Delete() string {
+	return fmt.Sprintf("DELETE FROM %s", b.table())
+}
+
+func (b *endpointQueryBuilder) DeleteByID() string {
+	return fmt.Sprintf("%s WHERE id=%s", b.Delete(), b.dialect.Placeholder(1))
+}
+
+// Where compiles predicates into a single "a AND b AND c" fragment (without
+// the leading WHERE keyword) plus their positional arguments in order.
+// Returns an empty string and nil args when predicates is empty.
+func (b *endpointQueryBuilder) Where(predicates []storage.Predicate) (string, []interface{}) {
+	if len(predicates) == 0 {
+		return "", nil
+	}
+
+	n := 0
+	next := func() string {
+		n++
+		return b.dialect.Placeholder(n)
+	}
+
+	clauses := make([]string, 0, len(predicates))
+	args := make([]interface{}, 0, len(predicates))
+
+	for _, predicate := range predicates {
+		clause, predicateArgs := predicate.Compile(next)
+		clauses = append(clauses, clause)
+		args = append(args, predicateArgs...)
+	}
+
+	return strings.Join(clauses, " AND "), args
+}
+
+// Limit renders a pagination clause with a zero offset, used by cursor-based
+// pagination where the cursor predicate takes the place of OFFSET.
+func (b *endpointQueryBuilder) Limit(n int) string {
+	return b.dialect.LimitOffset(n, 0)
+}
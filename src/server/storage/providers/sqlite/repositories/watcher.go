@@ -0,0 +1,147 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+
+	"github.com/blent/beagle/src/server/storage"
+)
+
+const consumerBufferSize = 16
+
+// SQLiteWatcher is the SQLite-backed storage.Watcher/storage.Producer pair
+// that SQLiteEndpointRepository publishes to. No peripheral repository
+// publishes to it, so subscribers receive endpoint events only. A
+// single goroutine multiplexes published events to every registered
+// subscriber, filtering by kind and op. Subscribers that fall behind have
+// events dropped rather than blocking the publisher; the dropped count is
+// tracked per subscriber for diagnostics.
+type SQLiteWatcher struct {
+	mu     sync.Mutex
+	subs   map[uint64]*subscriber
+	nextID uint64
+	events chan storage.Event
+	done   chan struct{}
+	closed bool
+}
+
+type subscriber struct {
+	filter  storage.Filter
+	ch      chan storage.Event
+	dropped uint64
+}
+
+// NewSQLiteWatcher creates a watcher and starts its dispatch loop.
+func NewSQLiteWatcher() *SQLiteWatcher {
+	w := &SQLiteWatcher{
+		subs:   make(map[uint64]*subscriber),
+		events: make(chan storage.Event, 64),
+		done:   make(chan struct{}),
+	}
+
+	go w.loop()
+
+	return w
+}
+
+func (w *SQLiteWatcher) loop() {
+	for {
+		select {
+		case event := <-w.events:
+			w.dispatch(event)
+		case <-w.done:
+			return
+		}
+	}
+}
+
+func (w *SQLiteWatcher) dispatch(event storage.Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, sub := range w.subs {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+
+		select {
+		case sub.ch <- event:
+		default:
+			sub.dropped++
+		}
+	}
+}
+
+// Publish implements storage.Producer. It is safe to call concurrently and
+// never blocks; once the watcher is closed, published events are discarded.
+func (w *SQLiteWatcher) Publish(event storage.Event) {
+	w.mu.Lock()
+	closed := w.closed
+	w.mu.Unlock()
+
+	if closed {
+		return
+	}
+
+	select {
+	case w.events <- event:
+	default:
+	}
+}
+
+// Subscribe implements storage.Watcher.
+func (w *SQLiteWatcher) Subscribe(ctx context.Context, filter storage.Filter) (storage.Consumer, error) {
+	w.mu.Lock()
+
+	if w.closed {
+		w.mu.Unlock()
+		return nil, storage.ErrWatcherClosed
+	}
+
+	id := w.nextID
+	w.nextID++
+
+	sub := &subscriber{
+		filter: filter,
+		ch:     make(chan storage.Event, consumerBufferSize),
+	}
+	w.subs[id] = sub
+
+	w.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		if _, ok := w.subs[id]; !ok {
+			return
+		}
+
+		delete(w.subs, id)
+		close(sub.ch)
+	}()
+
+	return sub.ch, nil
+}
+
+// Close implements storage.Watcher.
+func (w *SQLiteWatcher) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return storage.ErrWatcherClosed
+	}
+
+	w.closed = true
+	close(w.done)
+
+	for id, sub := range w.subs {
+		delete(w.subs, id)
+		close(sub.ch)
+	}
+
+	return nil
+}
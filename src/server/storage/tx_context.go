@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+)
+
+// TryToBeginContext is the context-aware counterpart of TryToBegin: when tx
+// is nil it starts a new transaction via db.BeginTx(ctx, nil) so cancelling
+// ctx actually aborts in-flight statements instead of just failing to
+// observe their result; when tx is non-nil (the caller already owns a
+// transaction) it is returned unchanged and closeTx is false.
+func TryToBeginContext(ctx context.Context, db *sql.DB, tx *sql.Tx) (*sql.Tx, bool, error) {
+	if tx != nil {
+		return tx, false, nil
+	}
+
+	newTx, err := db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return newTx, true, nil
+}
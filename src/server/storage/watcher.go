@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// EntityKind identifies the kind of entity a watcher Event refers to.
+type EntityKind string
+
+const (
+	EntityKindEndpoint   EntityKind = "endpoint"
+	EntityKindPeripheral EntityKind = "peripheral"
+)
+
+// Op identifies the kind of mutation that produced a watcher Event.
+type Op string
+
+const (
+	OpCreate Op = "create"
+	OpUpdate Op = "update"
+	OpDelete Op = "delete"
+)
+
+// ErrWatcherClosed is returned by Watcher methods once the watcher has
+// been closed. Existing subscribers are not sent this error; their Consumer
+// is closed instead.
+var ErrWatcherClosed = errors.New("watcher is closed")
+
+// Event describes a single Create/Update/Delete mutation committed to storage.
+type Event struct {
+	EntityKind EntityKind
+	Op         Op
+	ID         uint64
+	Payload    interface{}
+}
+
+// Filter restricts the events a subscriber receives. A nil or empty slice
+// matches every kind/op respectively.
+type Filter struct {
+	Kinds []EntityKind
+	Ops   []Op
+}
+
+// Matches reports whether the event satisfies the filter.
+func (f Filter) Matches(event Event) bool {
+	if len(f.Kinds) > 0 && !containsKind(f.Kinds, event.EntityKind) {
+		return false
+	}
+
+	if len(f.Ops) > 0 && !containsOp(f.Ops, event.Op) {
+		return false
+	}
+
+	return true
+}
+
+func containsKind(kinds []EntityKind, kind EntityKind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsOp(ops []Op, op Op) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Consumer is a read-only stream of events matching the Filter it was
+// obtained with. It is closed once the subscribing context is done or the
+// owning Watcher is closed.
+type Consumer <-chan Event
+
+// Watcher lets components subscribe to storage mutations without polling.
+type Watcher interface {
+	// Subscribe registers a new consumer matching filter. The returned
+	// Consumer is closed when ctx is cancelled or the Watcher itself is
+	// closed.
+	Subscribe(ctx context.Context, filter Filter) (Consumer, error)
+	// Close shuts the watcher down, closing every active Consumer.
+	Close() error
+}
+
+// Producer is implemented by stores that emit Events after a successful
+// mutation. It is the write-side counterpart of Watcher.
+type Producer interface {
+	Publish(event Event)
+}